@@ -1,20 +1,42 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/<your-github-username>/learning-go-by-building/internal/handler"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/SamBird/learning-go-by-building/internal/config"
+	"github.com/SamBird/learning-go-by-building/internal/handler"
+	"github.com/SamBird/learning-go-by-building/internal/handler/middleware"
+	"github.com/SamBird/learning-go-by-building/internal/idempotency"
+	"github.com/SamBird/learning-go-by-building/internal/metrics"
+	"github.com/SamBird/learning-go-by-building/internal/sink"
+	"github.com/SamBird/learning-go-by-building/pkg/model"
 )
 
 func main() {
 	// Notes to self:
-	// - log.New lets me control output + prefix/flags.
-	// - LUTC prints timestamps in UTC which is usually better for systems logs.
-	// Ref: https://pkg.go.dev/log
-	logger := log.New(os.Stdout, "", log.LstdFlags|log.LUTC)
+	// - slog.NewJSONHandler gives structured, machine-parseable log lines out
+	//   of the box, which the Logging middleware relies on for per-request fields.
+	// Ref: https://pkg.go.dev/log/slog
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	cfg := config.Load()
+
+	// ctx is cancelled the moment SIGINT/SIGTERM arrives, giving in-flight
+	// handlers (via EventHandler.ctx) and the shutdown sequence below a
+	// shared signal to act on.
+	// Ref: https://pkg.go.dev/os/signal#NotifyContext
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// ServeMux is the built-in router.
 	// Notes to self:
@@ -22,28 +44,156 @@ func main() {
 	// - Frameworks are great later; stdlib teaches the fundamentals.
 	mux := http.NewServeMux()
 
-	h := handler.NewEventHandler(logger)
+	sk, err := newSink(cfg)
+	if err != nil {
+		logger.Error("failed to initialise sink", "error", err)
+		os.Exit(1)
+	}
+	if sp, ok := sk.(sink.StatsProvider); ok {
+		metrics.RegisterSinkStats(sinkName(cfg), sp.Snapshot)
+	}
+
+	schemas := loadSchemaRegistry(cfg, logger)
+
+	idem, err := newIdempotencyManager(cfg)
+	if err != nil {
+		logger.Error("failed to initialise idempotency store", "error", err)
+		os.Exit(1)
+	}
+
+	batchCfg := handler.BatchConfig{
+		MaxBytes:  cfg.BatchMaxBytes,
+		MaxEvents: cfg.BatchMaxEvents,
+		Workers:   cfg.BatchWorkers,
+	}
+
+	h := handler.NewEventHandler(ctx, logger, authValidator(cfg), sk, schemas, idem, batchCfg)
 	h.Register(mux)
 
-	// Configure HTTP server with a basic timeout.
+	// Wrap the whole mux in the shared middleware chain.
+	// Notes to self:
+	// - Auth is deliberately NOT in this global chain; EventHandler.Register
+	//   applies it per-route so /health stays public.
+	chained := middleware.Chain(mux,
+		middleware.RequestID,
+		middleware.Logging(logger),
+		middleware.Recovery(logger),
+	)
+
 	// Notes to self:
 	// - ReadHeaderTimeout protects against slowloris-style attacks.
-	// - Later: add ReadTimeout/WriteTimeout/IdleTimeout once I understand their tradeoffs.
+	// - ReadTimeout/WriteTimeout/IdleTimeout/MaxHeaderBytes now come from
+	//   config.Load() instead of being hardcoded.
 	// Ref: https://pkg.go.dev/net/http#Server
 	srv := &http.Server{
-		Addr:              ":8080",
-		Handler:           mux,
+		Addr:              cfg.Addr,
+		Handler:           chained,
 		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
 	}
 
-	logger.Printf("starting server on %s", srv.Addr)
+	// Run the server in a goroutine so main can block on ctx.Done() instead.
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("starting server", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
 
-	// ListenAndServe blocks.
-	// Notes to self:
-	// - In the next iteration, add graceful shutdown (context + signal handling).
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		logger.Fatalf("server failed: %v", err)
+	select {
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("server failed", "error", err)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		logger.Info("shutdown signal received, draining in-flight requests", "grace", cfg.ShutdownGrace.String())
+
+		// Use a fresh context for Shutdown: ctx is already cancelled, and
+		// Shutdown needs its own deadline to bound how long it waits.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGrace)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("graceful shutdown failed", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("server shut down cleanly")
+	}
+}
+
+// authValidator builds the auth validator for POST /events from config. An
+// empty AuthToken disables auth, which keeps local dev friction-free.
+func authValidator(cfg config.Config) middleware.AuthValidator {
+	if cfg.AuthToken == "" {
+		return nil
+	}
+	return middleware.StaticTokenValidator{Token: cfg.AuthToken}
+}
+
+// newSink builds the configured Sink. Kafka/NATS sinks are opt-in at build
+// time (see internal/sink) and aren't selectable here yet.
+func newSink(cfg config.Config) (sink.Sink, error) {
+	switch cfg.SinkType {
+	case "file":
+		return sink.NewFileSink(cfg.FileSinkPath, cfg.FileSinkMaxBytes, cfg.FileSinkQueueSize)
+	case "memory", "":
+		return sink.NewMemorySink(cfg.MemorySinkCapacity), nil
+	default:
+		return nil, fmt.Errorf("unknown SINK_TYPE %q", cfg.SinkType)
+	}
+}
+
+// sinkName returns the configured sink type for labeling metrics, applying
+// the same "memory" default newSink uses for an unset SINK_TYPE.
+func sinkName(cfg config.Config) string {
+	if cfg.SinkType == "" {
+		return "memory"
+	}
+	return cfg.SinkType
+}
+
+// newIdempotencyManager builds the Manager backing Idempotency-Key support
+// for POST /events, or nil if it's disabled in config.
+func newIdempotencyManager(cfg config.Config) (*idempotency.Manager, error) {
+	if !cfg.IdempotencyEnabled {
+		return nil, nil
+	}
+
+	var store idempotency.Store
+	switch cfg.IdempotencyStoreType {
+	case "redis":
+		client := redis.NewClient(&redis.Options{Addr: cfg.IdempotencyRedisAddr})
+		store = idempotency.NewRedisStore(client, cfg.IdempotencyRedisPrefix)
+	case "memory", "":
+		store = idempotency.NewMemoryStore()
+	default:
+		return nil, fmt.Errorf("unknown IDEMPOTENCY_STORE_TYPE %q", cfg.IdempotencyStoreType)
+	}
+
+	return idempotency.NewManager(store, cfg.IdempotencyTTL), nil
+}
+
+// loadSchemaRegistry loads cfg.SchemaDir into a model.SchemaRegistry. A
+// missing directory is treated as "no schemas yet" rather than a startup
+// failure, since rolling out schemas is meant to be incremental.
+func loadSchemaRegistry(cfg config.Config, logger *slog.Logger) *model.SchemaRegistry {
+	if cfg.SchemaDir == "" {
+		return nil
+	}
+
+	reg, err := model.LoadSchemaRegistry(cfg.SchemaDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Info("schema dir not found, skipping payload schema validation", "dir", cfg.SchemaDir)
+			return nil
+		}
+		logger.Error("failed to load schema registry", "error", err)
+		os.Exit(1)
 	}
+	return reg
 }
 
 /*
@@ -51,4 +201,6 @@ Useful links (notes to self):
 - HTTP server basics: https://pkg.go.dev/net/http
 - Go Proverbs (idioms): https://go-proverbs.github.io/
 - Effective Go (general style): https://go.dev/doc/effective_go
+- log/slog: https://pkg.go.dev/log/slog
+- Graceful shutdown: https://pkg.go.dev/net/http#Server.Shutdown
 */
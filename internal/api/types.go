@@ -0,0 +1,71 @@
+// Package api holds the typed request/response shapes described by
+// api/openapi.yaml.
+//
+// Notes to self:
+//   - These are hand-maintained for now rather than produced by oapi-codegen;
+//     api/openapi.yaml is the source of truth, and this file is what codegen
+//     would replace if/when that gets wired into the build. Keeping them in
+//     sync by hand is the cost of not having that tool in the toolchain yet.
+//   - EventHandler decodes into PostEventsRequest and converts via ToEvent so
+//     the handler stays a thin adapter over the wire contract rather than
+//     coupling directly to model.Event's JSON tags.
+package api
+
+import (
+	"time"
+
+	"github.com/SamBird/learning-go-by-building/pkg/model"
+)
+
+// PostEventsRequest mirrors the requestBody schema for POST /events.
+type PostEventsRequest struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Source    string     `json:"source"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Payload   any        `json:"payload"`
+}
+
+// ToEvent converts a wire request into the internal model.Event, defaulting
+// Timestamp server-side the same way handlePostEvent always has.
+func (req PostEventsRequest) ToEvent() model.Event {
+	ts := time.Now().UTC()
+	if req.Timestamp != nil {
+		ts = *req.Timestamp
+	}
+	return model.Event{
+		ID:        req.ID,
+		Type:      req.Type,
+		Source:    req.Source,
+		Timestamp: ts,
+		Payload:   req.Payload,
+	}
+}
+
+// PostEventsResponse mirrors the 202 response body.
+type PostEventsResponse struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+}
+
+// ErrorResponse mirrors the error response body used across 4xx/5xx
+// responses; it's the typed counterpart of httputil.JSONError's shape.
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Details string `json:"details,omitempty"`
+}
+
+// BatchEventError describes why a single event within a POST /events/batch
+// request was rejected.
+type BatchEventError struct {
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// PostEventsBatchResponse mirrors the 202/207/400 response body for
+// POST /events/batch.
+type PostEventsBatchResponse struct {
+	Accepted int               `json:"accepted"`
+	Rejected []BatchEventError `json:"rejected"`
+}
@@ -0,0 +1,163 @@
+// Package config loads runtime configuration from environment variables.
+//
+// Notes to self:
+//   - Keeping this as plain env vars (no flags/yaml/viper) fits a small service
+//     that mostly runs in containers; env vars are the lowest-ceremony option.
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds everything main needs to start the HTTP server.
+type Config struct {
+	Addr string
+
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+
+	// ShutdownGrace bounds how long we wait for in-flight requests to finish
+	// once a shutdown signal arrives before forcing the process to exit.
+	ShutdownGrace time.Duration
+
+	// AuthToken guards POST /events when non-empty. See authValidator
+	// in cmd/event-ingest for how this gets turned into a validator.
+	AuthToken string
+
+	// SinkType selects the Sink implementation: "memory" (default) or "file".
+	// Kafka/NATS sinks are opt-in at build time (see internal/sink) and
+	// aren't wired through config yet.
+	SinkType string
+
+	MemorySinkCapacity int
+
+	FileSinkPath      string
+	FileSinkMaxBytes  int64
+	FileSinkQueueSize int
+
+	// SchemaDir, if non-empty, is loaded into a model.SchemaRegistry at
+	// startup to validate event payloads per type. A missing directory just
+	// disables schema validation rather than failing startup.
+	SchemaDir string
+
+	// IdempotencyEnabled turns on Idempotency-Key support for POST /events.
+	IdempotencyEnabled bool
+
+	// IdempotencyTTL bounds how long a cached response is replayed for a
+	// given key before it's eligible to be reused for a new request.
+	IdempotencyTTL time.Duration
+
+	// IdempotencyStoreType selects the idempotency.Store implementation:
+	// "memory" (default) or "redis".
+	IdempotencyStoreType string
+
+	// IdempotencyRedisAddr/Prefix are only used when IdempotencyStoreType is
+	// "redis".
+	IdempotencyRedisAddr   string
+	IdempotencyRedisPrefix string
+
+	// BatchMaxBytes bounds the request body size for POST /events/batch via
+	// http.MaxBytesReader, same as the single-event endpoint but larger since
+	// a batch carries many events.
+	BatchMaxBytes int64
+
+	// BatchMaxEvents rejects a batch outright once it carries more than this
+	// many events, rather than accepting an unbounded NDJSON stream.
+	BatchMaxEvents int
+
+	// BatchWorkers bounds how many events in a batch are validated/published
+	// concurrently, so one large batch can't starve other requests.
+	BatchWorkers int
+}
+
+// Load reads Config from the environment, falling back to sane defaults for
+// anything unset.
+func Load() Config {
+	return Config{
+		Addr:           ":" + stringEnv("PORT", "8080"),
+		ReadTimeout:    durationEnv("READ_TIMEOUT", 5*time.Second),
+		WriteTimeout:   durationEnv("WRITE_TIMEOUT", 10*time.Second),
+		IdleTimeout:    durationEnv("IDLE_TIMEOUT", 120*time.Second),
+		MaxHeaderBytes: intEnv("MAX_HEADER_BYTES", 1<<20),
+		ShutdownGrace:  durationEnv("SHUTDOWN_GRACE", 15*time.Second),
+		AuthToken:      stringEnv("AUTH_TOKEN", ""),
+
+		SinkType:           stringEnv("SINK_TYPE", "memory"),
+		MemorySinkCapacity: intEnv("MEMORY_SINK_CAPACITY", 1000),
+
+		FileSinkPath:      stringEnv("FILE_SINK_PATH", "events.jsonl"),
+		FileSinkMaxBytes:  int64Env("FILE_SINK_MAX_BYTES", 64<<20),
+		FileSinkQueueSize: intEnv("FILE_SINK_QUEUE_SIZE", 1000),
+
+		SchemaDir: stringEnv("SCHEMA_DIR", "schemas"),
+
+		IdempotencyEnabled:     boolEnv("IDEMPOTENCY_ENABLED", true),
+		IdempotencyTTL:         durationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyStoreType:   stringEnv("IDEMPOTENCY_STORE_TYPE", "memory"),
+		IdempotencyRedisAddr:   stringEnv("IDEMPOTENCY_REDIS_ADDR", "localhost:6379"),
+		IdempotencyRedisPrefix: stringEnv("IDEMPOTENCY_REDIS_PREFIX", "idempotency:"),
+
+		BatchMaxBytes:  int64Env("BATCH_MAX_BYTES", 16<<20),
+		BatchMaxEvents: intEnv("BATCH_MAX_EVENTS", 1000),
+		BatchWorkers:   intEnv("BATCH_WORKERS", 8),
+	}
+}
+
+func stringEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+func intEnv(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func boolEnv(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+func int64Env(key string, fallback int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
@@ -1,12 +1,29 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/SamBird/learning-go-by-building/internal/api"
+	"github.com/SamBird/learning-go-by-building/internal/handler/middleware"
+	"github.com/SamBird/learning-go-by-building/internal/httputil"
+	"github.com/SamBird/learning-go-by-building/internal/idempotency"
+	"github.com/SamBird/learning-go-by-building/internal/metrics"
+	"github.com/SamBird/learning-go-by-building/internal/sink"
 	"github.com/SamBird/learning-go-by-building/pkg/model"
 )
 
@@ -16,22 +33,102 @@ import (
 // - This is "poor man's DI": pass dependencies in via a constructor.
 // - It keeps things testable without bringing in a framework.
 type EventHandler struct {
-	Logger *log.Logger
+	Logger *slog.Logger
+
+	// ctx is the application's top-level context. It's cancelled once a
+	// shutdown signal arrives; requestContext merges it into each request's
+	// context so long-running work (e.g. a large batch publish) observes
+	// shutdown instead of running unbounded past it.
+	ctx context.Context
+
+	// authValidator guards POST /events. A nil value disables auth, which is
+	// handy for local dev / tests.
+	authValidator middleware.AuthValidator
+
+	sink sink.Sink
+
+	// schemas validates Payload per event type. A nil registry is permissive.
+	schemas *model.SchemaRegistry
+
+	// typeCardinality/sourceCardinality bound the type/source label
+	// values reported on events_ingested_total, since both come from
+	// client-supplied JSON.
+	typeCardinality   *metrics.CardinalityGuard
+	sourceCardinality *metrics.CardinalityGuard
+
+	// idem replays a cached response for a repeated Idempotency-Key instead
+	// of re-running processEvent. A nil value disables idempotency support.
+	idem *idempotency.Manager
+
+	// batch bounds POST /events/batch: max body size, max event count, and
+	// how many events are validated/published concurrently.
+	batch BatchConfig
+}
+
+// BatchConfig bounds POST /events/batch.
+type BatchConfig struct {
+	// MaxBytes is enforced via http.MaxBytesReader, same mechanism as the
+	// single-event endpoint.
+	MaxBytes int64
+
+	// MaxEvents rejects the whole batch once the stream carries more than
+	// this many events, rather than accepting an unbounded NDJSON stream.
+	MaxEvents int
+
+	// Workers bounds how many events are validated/published concurrently.
+	// Defaults to 1 (fully sequential) if left at zero.
+	Workers int
 }
 
-func NewEventHandler(logger *log.Logger) *EventHandler {
-	return &EventHandler{Logger: logger}
+// NewEventHandler wires up an EventHandler. Pass a nil authValidator to
+// leave /events unauthenticated, a nil schemas to skip per-type payload
+// validation, and a nil idem to skip Idempotency-Key support.
+func NewEventHandler(ctx context.Context, logger *slog.Logger, authValidator middleware.AuthValidator, sk sink.Sink, schemas *model.SchemaRegistry, idem *idempotency.Manager, batch BatchConfig) *EventHandler {
+	return &EventHandler{
+		ctx:               ctx,
+		Logger:            logger,
+		authValidator:     authValidator,
+		sink:              sk,
+		schemas:           schemas,
+		typeCardinality:   metrics.NewCardinalityGuard(metrics.DefaultCardinalityCap),
+		sourceCardinality: metrics.NewCardinalityGuard(metrics.DefaultCardinalityCap),
+		idem:              idem,
+		batch:             batch,
+	}
+}
+
+// requestContext derives a context from reqCtx that is also canceled once
+// h.ctx is canceled (app shutdown), so in-flight work started by a handler
+// can select on ctx.Done() instead of running unbounded past shutdown. The
+// returned cancel must be called once the request is done, same as
+// context.WithCancel, to release the context.AfterFunc watcher.
+func (h *EventHandler) requestContext(reqCtx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(reqCtx)
+	stop := context.AfterFunc(h.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
 }
 
 // Register wires endpoints into a ServeMux.
 //
 // Notes to self:
-// - net/http + ServeMux is perfectly fine for a lot of services.
-// - The "METHOD /path" patterns are supported in newer Go versions (Go 1.22+).
-//   If this ever fails in older versions, switch to mux.HandleFunc("/events", ...) + method checks.
+//   - net/http + ServeMux is perfectly fine for a lot of services.
+//   - The "METHOD /path" patterns are supported in newer Go versions (Go 1.22+).
+//     If this ever fails in older versions, switch to mux.HandleFunc("/events", ...) + method checks.
+//   - Auth is applied per-route here rather than globally, so /health stays public.
+//   - GET /events is a debug endpoint backed by sink.Lister; it returns 501
+//     for sinks (file, Kafka, NATS) that don't support listing everything back.
+//   - GET /metrics is public, same as /health; scraping doesn't carry the
+//     bearer token required for POST /events.
+//   - POST /events/batch carries the same auth as POST /events.
 func (h *EventHandler) Register(mux *http.ServeMux) {
-	mux.HandleFunc("POST /events", h.handlePostEvent)
+	mux.Handle("POST /events", middleware.Auth(h.authValidator)(http.HandlerFunc(h.handlePostEvent)))
+	mux.Handle("POST /events/batch", middleware.Auth(h.authValidator)(http.HandlerFunc(h.handlePostEventsBatch)))
+	mux.Handle("GET /events", middleware.Auth(h.authValidator)(http.HandlerFunc(h.handleListEvents)))
 	mux.HandleFunc("GET /health", h.handleHealth)
+	mux.Handle("GET /metrics", promhttp.Handler())
 }
 
 // handleHealth is a simple liveness endpoint.
@@ -42,83 +139,376 @@ func (h *EventHandler) handleHealth(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte("ok"))
 }
 
-// handlePostEvent accepts an event payload, validates it, and (for now) logs it.
+// handlePostEvent accepts an event payload, validates it, and publishes it.
+//
+// Notes to self:
+//   - The whole body is read up front (bounded by MaxBytesReader) rather than
+//     streamed straight into json.Decoder, because Idempotency-Key support
+//     needs to hash the raw bytes and potentially replay a cached response
+//     without re-running processEvent at all.
 func (h *EventHandler) handlePostEvent(w http.ResponseWriter, r *http.Request) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	ctx, cancel := h.requestContext(r.Context())
+	defer cancel()
+
 	// Notes to self:
 	// - Always protect against huge payloads. MaxBytesReader prevents memory bloat / DoS-ish behaviour.
 	// - 1<<20 = 1,048,576 bytes = 1MB (fine for v1).
 	// Ref: https://pkg.go.dev/net/http#MaxBytesReader
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 
-	var evt model.Event
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		metrics.RecordRejected("body_read_error")
+		httputil.JSONError(w, http.StatusBadRequest, "failed to read request body", err)
+		return
+	}
+
+	idemKey := r.Header.Get("Idempotency-Key")
+	if idemKey == "" || h.idem == nil {
+		status, body := h.processEvent(ctx, logger, raw)
+		writeJSONResponse(w, status, body)
+		return
+	}
+
+	entry, replayed, err := h.idem.Execute(ctx, idemKey, hashBody(raw), func(ctx context.Context) (idempotency.Entry, error) {
+		status, body := h.processEvent(ctx, logger, raw)
+		return idempotency.Entry{Status: status, Body: body}, nil
+	})
+	if err != nil {
+		if errors.Is(err, idempotency.ErrConflict) {
+			httputil.JSONError(w, http.StatusConflict, "idempotency key reused with a different request body", err)
+			return
+		}
+		httputil.JSONError(w, http.StatusInternalServerError, "idempotency store error", err)
+		return
+	}
+	if replayed {
+		logger.Info("replayed idempotent response", "idempotency_key", idemKey)
+	}
+	writeJSONResponse(w, entry.Status, entry.Body)
+}
+
+// processEvent decodes, validates, and publishes a single event, returning
+// the status code and JSON body to send the client. It never fails outright:
+// any error becomes an error-shaped (status, body) pair so both the direct
+// and idempotency-replayed paths in handlePostEvent can treat success and
+// failure the same way.
+func (h *EventHandler) processEvent(ctx context.Context, logger *slog.Logger, raw []byte) (int, []byte) {
+	var req api.PostEventsRequest
 
 	// json.Decoder is streaming-friendly vs reading the whole body then Unmarshal.
 	// Ref: https://pkg.go.dev/encoding/json#Decoder
-	dec := json.NewDecoder(r.Body)
+	// Here the body is already fully read (see handlePostEvent); the Decoder is
+	// kept for DisallowUnknownFields and the More check below.
+	dec := json.NewDecoder(bytes.NewReader(raw))
 
 	// DisallowUnknownFields is useful to catch typos early (client sends "soucre" etc).
 	// Caveat: it can be strict when payloads evolve; for v1 it's good discipline.
 	// Ref: https://pkg.go.dev/encoding/json#Decoder.DisallowUnknownFields
 	dec.DisallowUnknownFields()
 
-	// Decode into our struct (JSON -> Go struct).
-	if err := dec.Decode(&evt); err != nil {
-		httpError(w, http.StatusBadRequest, "invalid JSON payload", err)
-		return
+	// Decode into the typed wire struct from internal/api (mirrors
+	// api/openapi.yaml) rather than straight into model.Event.
+	if err := dec.Decode(&req); err != nil {
+		metrics.RecordRejected("invalid_json")
+		return http.StatusBadRequest, httputil.JSONErrorBody("invalid JSON payload", err)
 	}
 
 	// Notes to self:
 	// - This is a small safety check to ensure the request isn't "valid JSON + extra junk".
 	// - Helps avoid weird edge cases where multiple JSON values are sent.
 	if dec.More() {
-		httpError(w, http.StatusBadRequest, "unexpected extra JSON content", errors.New("multiple JSON values"))
-		return
-	}
-
-	// Default timestamp server-side if it's missing.
-	// Notes to self:
-	// - UTC is a good default for server logs + events.
-	// - RFC3339 is the common wire-format.
-	if evt.Timestamp.IsZero() {
-		evt.Timestamp = time.Now().UTC()
+		metrics.RecordRejected("extra_json_content")
+		return http.StatusBadRequest, httputil.JSONErrorBody("unexpected extra JSON content", errors.New("multiple JSON values"))
 	}
 
-	// Validate required fields.
-	if err := evt.Validate(); err != nil {
-		httpError(w, http.StatusBadRequest, "validation failed", err)
-		return
+	evt, err := h.validateAndPublish(ctx, req)
+	if err != nil {
+		if errors.Is(err, sink.ErrBackpressure) {
+			metrics.RecordRejected("backpressure")
+			return http.StatusServiceUnavailable, httputil.JSONErrorBody("sink is backpressured", err)
+		}
+		if errors.Is(err, errValidationFailed) {
+			metrics.RecordRejected("validation_failed")
+			return http.StatusBadRequest, httputil.JSONErrorBody("validation failed", err)
+		}
+		metrics.RecordRejected("publish_error")
+		return http.StatusInternalServerError, httputil.JSONErrorBody("failed to publish event", err)
 	}
 
-	// Structured logging is the next step, but for now a consistent message format is fine.
-	// Later: swap to slog (Go's structured logger) or zap/zerolog.
-	h.Logger.Printf(
-		"event accepted: id=%s type=%s source=%s ts=%s",
-		evt.ID,
-		evt.Type,
-		evt.Source,
-		evt.Timestamp.Format(time.RFC3339),
+	// Structured logging via the request-scoped logger (tagged with
+	// request_id by the Logging middleware) instead of a bare *log.Logger.
+	logger.Info("event accepted",
+		"event_id", evt.ID,
+		"type", evt.Type,
+		"source", evt.Source,
+		"timestamp", evt.Timestamp.Format(time.RFC3339),
 	)
 
-	// Reply to the client.
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-
-	_ = json.NewEncoder(w).Encode(map[string]any{
+	body, _ := json.Marshal(map[string]any{
 		"status": "accepted",
 		"id":     evt.ID,
 	})
+	return http.StatusAccepted, body
 }
 
-// httpError returns a consistent JSON error shape.
-// Notes to self:
-// - Keep error responses consistent from day one; it helps clients + debugging.
-// - "details" can leak internal info in real services; later we might hide it in prod mode.
-func httpError(w http.ResponseWriter, status int, message string, err error) {
+// validateAndPublish defaults the event's timestamp, validates it (including
+// against the registered schema for its type, if any), and publishes it to
+// the configured Sink. It also records events_ingested_total on success; the
+// caller is responsible for events_rejected_total since the right "reason"
+// label depends on context the caller has (e.g. whether this is part of a
+// batch).
+func (h *EventHandler) validateAndPublish(ctx context.Context, req api.PostEventsRequest) (model.Event, error) {
+	evt := req.ToEvent()
+
+	if err := evt.ValidateWithRegistry(h.schemas); err != nil {
+		return model.Event{}, fmt.Errorf("%w: %w", errValidationFailed, err)
+	}
+
+	if err := h.sink.Publish(ctx, evt); err != nil {
+		return model.Event{}, err
+	}
+
+	metrics.RecordIngested(h.typeCardinality.Label(evt.Type), h.sourceCardinality.Label(evt.Source))
+	return evt, nil
+}
+
+func writeJSONResponse(w http.ResponseWriter, status int, body []byte) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func hashBody(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// errValidationFailed marks a validateAndPublish failure as a 400-shaped
+// client error rather than a 500-shaped sink/publish error. Wrapped around
+// the underlying error so errors.Is still works alongside the normal
+// %w-wrapping chain.
+var errValidationFailed = errors.New("event failed validation")
+
+// handlePostEventsBatch accepts a stream of newline-delimited JSON events,
+// validates and publishes each independently, and reports a per-event
+// accept/reject outcome instead of failing the whole request for one bad
+// event.
+//
+// Notes to self:
+//   - Decoding stays sequential (json.Decoder reads one io.Reader), but
+//     validation + publish for each decoded event runs on a small worker
+//     pool so a batch of, say, 1000 events can't monopolise the sink one
+//     event at a time while other requests wait.
+//   - A malformed element aborts the whole stream with 400, since a
+//     json.Decoder that hits bad JSON mid-stream can't reliably resync to
+//     the next NDJSON line. Only validation/publish failures for an
+//     otherwise well-formed event end up in the per-event "rejected" list.
+func (h *EventHandler) handlePostEventsBatch(w http.ResponseWriter, r *http.Request) {
+	logger := middleware.LoggerFromContext(r.Context())
+
+	ctx, cancel := h.requestContext(r.Context())
+	defer cancel()
+
+	maxBytes := h.batch.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 16 << 20
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	maxEvents := h.batch.MaxEvents
+	if maxEvents <= 0 {
+		maxEvents = 1000
+	}
+
+	dec := json.NewDecoder(r.Body)
+
+	// Same strictness as the single-event path (processEvent): catch typo'd
+	// fields instead of silently ignoring them.
+	dec.DisallowUnknownFields()
+
+	var reqs []api.PostEventsRequest
+	for {
+		var req api.PostEventsRequest
+		if err := dec.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			metrics.RecordRejected("invalid_ndjson")
+			httputil.JSONError(w, http.StatusBadRequest, "malformed NDJSON stream", err)
+			return
+		}
+		if len(reqs) >= maxEvents {
+			metrics.RecordRejected("batch_too_large")
+			httputil.JSONError(w, http.StatusBadRequest, "batch exceeds max event count",
+				fmt.Errorf("max %d events per batch", maxEvents))
+			return
+		}
+		reqs = append(reqs, req)
+	}
+
+	rejected := h.publishBatch(ctx, logger, reqs)
+	accepted := len(reqs) - len(rejected)
+
+	status := http.StatusAccepted
+	switch {
+	case len(reqs) == 0:
+		status = http.StatusAccepted
+	case accepted == 0:
+		status = http.StatusBadRequest
+	case len(rejected) > 0:
+		status = http.StatusMultiStatus
+	}
+
+	body, _ := json.Marshal(api.PostEventsBatchResponse{
+		Accepted: accepted,
+		Rejected: rejected,
+	})
+	writeJSONResponse(w, status, body)
+}
+
+// publishBatch runs validateAndPublish for each request on a bounded worker
+// pool and returns the rejections, ordered by index.
+func (h *EventHandler) publishBatch(ctx context.Context, logger *slog.Logger, reqs []api.PostEventsRequest) []api.BatchEventError {
+	workers := h.batch.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+
+	type indexed struct {
+		index int
+		req   api.PostEventsRequest
+	}
+	type outcome struct {
+		index int
+		id    string
+		err   error
+	}
+
+	jobs := make(chan indexed)
+	outcomes := make(chan outcome, len(reqs))
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				// Run each job in its own func so a panic recovers per-event
+				// instead of killing the whole worker goroutine (and, since
+				// Recovery middleware only wraps the request goroutine, the
+				// whole process).
+				func() {
+					defer func() {
+						if rec := recover(); rec != nil {
+							logger.Error("panic recovered in batch worker",
+								"error", fmt.Sprintf("%v", rec),
+								"stack", string(debug.Stack()),
+							)
+							metrics.RecordRejected("panic")
+							outcomes <- outcome{index: job.index, id: job.req.ID, err: errors.New("internal error processing event")}
+						}
+					}()
+
+					evt, err := h.validateAndPublish(ctx, job.req)
+					if err != nil {
+						switch {
+						case errors.Is(err, errValidationFailed):
+							metrics.RecordRejected("validation_failed")
+						case errors.Is(err, sink.ErrBackpressure):
+							metrics.RecordRejected("backpressure")
+						default:
+							metrics.RecordRejected("publish_error")
+						}
+						outcomes <- outcome{index: job.index, id: job.req.ID, err: err}
+						return
+					}
+					outcomes <- outcome{index: job.index, id: evt.ID}
+				}()
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i, req := range reqs {
+			select {
+			case <-ctx.Done():
+				// Shutting down (or the client went away): stop handing out
+				// new work rather than starting jobs that'll just be
+				// abandoned mid-publish.
+				return
+			case jobs <- indexed{index: i, req: req}:
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	seen := make([]bool, len(reqs))
+	rejected := make([]api.BatchEventError, 0)
+	for o := range outcomes {
+		seen[o.index] = true
+		if o.err == nil {
+			continue
+		}
+		rejected = append(rejected, api.BatchEventError{
+			Index: o.index,
+			ID:    o.id,
+			Error: o.err.Error(),
+		})
+	}
+
+	// Any request never handed to a worker (feed loop stopped early on
+	// ctx.Done()) has no outcome yet; count it as rejected rather than
+	// silently dropping it from the accepted/rejected totals. ctx.Err() is
+	// normally why an item ends up here, but every worker path recovers its
+	// own panics and always sends an outcome, so this is just a defensive
+	// fallback rather than something that should ever fire for that reason.
+	for i, req := range reqs {
+		if seen[i] {
+			continue
+		}
+		metrics.RecordRejected("shutting_down")
+		errMsg := "event not processed"
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			errMsg = ctxErr.Error()
+		}
+		rejected = append(rejected, api.BatchEventError{
+			Index: i,
+			ID:    req.ID,
+			Error: errMsg,
+		})
+	}
+
+	sort.Slice(rejected, func(i, j int) bool { return rejected[i].Index < rejected[j].Index })
+
+	logger.Info("batch processed", "accepted", len(reqs)-len(rejected), "rejected", len(rejected))
+	return rejected
+}
+
+// handleListEvents is a debug endpoint backed by sink.Lister. It's only
+// meaningful for sinks that retain events in-process (MemorySink); other
+// sinks report 501 since "list everything" doesn't make sense once events
+// live in a file or a broker.
+func (h *EventHandler) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	lister, ok := h.sink.(sink.Lister)
+	if !ok {
+		httputil.JSONError(w, http.StatusNotImplemented, "sink does not support listing", errors.New("configured sink is not a sink.Lister"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]any{
-		"error":   message,
-		"details": err.Error(),
+		"events": lister.List(),
 	})
 }
 
@@ -127,4 +517,5 @@ Useful links (notes to self):
 - net/http package docs: https://pkg.go.dev/net/http
 - ServeMux patterns (Go 1.22): https://go.dev/blog/routing-enhancements
 - JSON decoding tips: https://pkg.go.dev/encoding/json
+- log/slog: https://pkg.go.dev/log/slog
 */
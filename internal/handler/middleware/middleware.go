@@ -0,0 +1,242 @@
+// Package middleware provides a small composable http.Handler chain.
+//
+// Notes to self:
+//   - Each middleware has the shape func(http.Handler) http.Handler so they
+//     compose cleanly with Chain below, no framework required.
+//   - Order matters: Chain wraps left-to-right, so the first middleware passed
+//     in is the outermost one (it sees the request first and the response last).
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/SamBird/learning-go-by-building/internal/httputil"
+	"github.com/SamBird/learning-go-by-building/internal/metrics"
+)
+
+// Middleware wraps an http.Handler with extra behaviour.
+type Middleware func(http.Handler) http.Handler
+
+// Chain applies mws to h in order, so mws[0] is the outermost handler.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	loggerKey
+)
+
+// RequestIDFromContext returns the request ID stashed by RequestID, or ""
+// if none is present (e.g. in a unit test that doesn't go through the chain).
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// LoggerFromContext returns the request-scoped logger stashed by Logging,
+// falling back to slog.Default so callers never need a nil check.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey).(*slog.Logger); ok && l != nil {
+		return l
+	}
+	return slog.Default()
+}
+
+// RequestID reads X-Request-Id from the incoming request, or generates a new
+// one, and makes it available via the context and the response header.
+//
+// Notes to self:
+//   - Not pulling in google/uuid here; 16 random bytes hex-encoded gives plenty
+//     of entropy for request correlation without a new dependency.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+		if id == "" {
+			id = newRequestID()
+		}
+
+		w.Header().Set("X-Request-Id", id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand.Read only fails if the OS RNG is broken; fall back to a
+		// timestamp-based ID rather than panicking mid-request.
+		return fmt.Sprintf("fallback-%d", time.Now().UTC().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// statusRecorder wraps a ResponseWriter so Logging can observe the status
+// code and byte count a handler actually wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Logging emits one structured log line per request via log/slog.
+//
+// Notes to self:
+//   - It stashes a request-scoped *slog.Logger (pre-tagged with request_id) in
+//     the context so downstream handlers can log with slog.With(...) and get
+//     consistent correlation for free.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			reqLogger := logger.With("request_id", RequestIDFromContext(r.Context()))
+			ctx := context.WithValue(r.Context(), loggerKey, reqLogger)
+
+			// Keep our own pointer to the request actually passed downstream:
+			// ServeMux sets Pattern on the *http.Request it receives, and since
+			// nothing below here calls WithContext again, routedReq is that
+			// same request by the time next.ServeHTTP returns.
+			routedReq := r.WithContext(ctx)
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, routedReq)
+
+			duration := time.Since(start)
+			metrics.ObserveHTTPRequest(r.Method, routeLabel(routedReq), rec.status, duration)
+
+			reqLogger.Info("request handled",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.status,
+				"bytes", rec.bytes,
+				"duration_ms", duration.Milliseconds(),
+				"remote_ip", remoteIP(r),
+			)
+		})
+	}
+}
+
+// routeLabel returns the matched mux pattern (e.g. "POST /events") for use as
+// a bounded-cardinality Prometheus label. r.URL.Path is client-controlled and
+// unbounded (404s, probes, path traversal attempts each mint a new value);
+// the pattern ServeMux actually matched against is not.
+func routeLabel(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return "unmatched"
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Recovery catches panics from downstream handlers, logs a stack trace, and
+// returns a 500 using the shared httputil.JSONError shape instead of letting
+// net/http close the connection with no response.
+func Recovery(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					reqLogger := LoggerFromContext(r.Context())
+					if reqLogger == nil {
+						reqLogger = logger
+					}
+					reqLogger.Error("panic recovered",
+						"error", fmt.Sprintf("%v", rec),
+						"stack", string(debug.Stack()),
+					)
+					httputil.JSONError(w, http.StatusInternalServerError, "internal server error", errors.New("unexpected error"))
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthValidator checks a bearer token extracted from the Authorization
+// header. Implementations can back this with a static secret, a call to an
+// identity provider, etc.
+type AuthValidator interface {
+	Validate(token string) error
+}
+
+// StaticTokenValidator accepts exactly one configured shared secret.
+// Notes to self:
+//   - Fine for a first pass / internal service-to-service auth; swap for
+//     something smarter (JWT, mTLS, an IdP) once there's a real need.
+type StaticTokenValidator struct {
+	Token string
+}
+
+func (v StaticTokenValidator) Validate(token string) error {
+	// subtle.ConstantTimeCompare instead of != so a shared-secret check
+	// doesn't leak how many leading bytes matched via response timing.
+	if token == "" || v.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(v.Token)) != 1 {
+		return errors.New("invalid bearer token")
+	}
+	return nil
+}
+
+// Auth validates a bearer token on requests that reach it. A nil validator
+// disables auth entirely, which lets callers apply this middleware only to
+// the routes that need it (e.g. POST /events) while leaving others public.
+func Auth(validator AuthValidator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if validator == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			const prefix = "Bearer "
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, prefix) {
+				httputil.JSONError(w, http.StatusUnauthorized, "missing bearer token", errors.New("authorization header must be 'Bearer <token>'"))
+				return
+			}
+
+			token := strings.TrimPrefix(header, prefix)
+			if err := validator.Validate(token); err != nil {
+				httputil.JSONError(w, http.StatusUnauthorized, "invalid bearer token", err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
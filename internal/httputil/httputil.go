@@ -0,0 +1,37 @@
+// Package httputil holds small HTTP helpers shared across handler and
+// middleware packages.
+//
+// Notes to self:
+//   - Pulling this out avoids an import cycle between internal/handler and
+//     internal/handler/middleware, both of which need to write the same
+//     JSON error shape.
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JSONErrorBody builds the same JSON error shape as JSONError, but returns
+// the encoded bytes instead of writing them. Useful when a caller needs the
+// body before deciding whether/how to write it (e.g. caching it for replay).
+func JSONErrorBody(message string, err error) []byte {
+	body := map[string]any{"error": message}
+	if err != nil {
+		body["details"] = err.Error()
+	}
+	// encoding/json.Marshal on a map[string]any only fails for unsupported
+	// types, which this literal never contains.
+	b, _ := json.Marshal(body)
+	return b
+}
+
+// JSONError writes a consistent JSON error shape.
+// Notes to self:
+// - Keep error responses consistent from day one; it helps clients + debugging.
+// - "details" can leak internal info in real services; later we might hide it in prod mode.
+func JSONError(w http.ResponseWriter, status int, message string, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(JSONErrorBody(message, err))
+}
@@ -0,0 +1,125 @@
+// Package idempotency lets POST /events be retried safely: a client-supplied
+// Idempotency-Key header gets the same response replayed on every retry
+// within the TTL instead of re-invoking the sink.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/SamBird/learning-go-by-building/internal/metrics"
+)
+
+// Entry is the cached outcome of handling a request for a given key. Only
+// terminal (non-5xx) outcomes are ever persisted to a Store; see Execute.
+type Entry struct {
+	Status    int
+	Body      []byte
+	BodyHash  string
+	ExpiresAt time.Time
+}
+
+// Store persists Entry values keyed by Idempotency-Key.
+// Notes to self:
+//   - Same shape as internal/sink.Sink: a small interface so MemoryStore can
+//     back local dev/tests and a Redis-backed Store can back production
+//     without EventHandler knowing the difference.
+type Store interface {
+	// Get returns the entry for key, or ok=false if there is none (or it has
+	// expired).
+	Get(ctx context.Context, key string) (entry Entry, ok bool, err error)
+	// Save stores entry for key. Implementations are responsible for
+	// expiring it after entry.ExpiresAt.
+	Save(ctx context.Context, key string, entry Entry) error
+}
+
+// ErrConflict is returned when a key is reused with a request body that
+// doesn't match the one it was first used with.
+var ErrConflict = errors.New("idempotency: key reused with a different request body")
+
+// Manager ties a Store to a singleflight.Group so concurrent requests with
+// the same key only run fn once, with everyone else waiting on the result
+// instead of racing the sink.
+type Manager struct {
+	store Store
+	ttl   time.Duration
+	group singleflight.Group
+}
+
+// NewManager returns a Manager backed by store, caching entries for ttl.
+func NewManager(store Store, ttl time.Duration) *Manager {
+	return &Manager{store: store, ttl: ttl}
+}
+
+// Execute returns the cached Entry for key if bodyHash matches what was
+// stored previously (replayed=true), runs fn and caches its result if key is
+// unseen, or returns ErrConflict if key is reused with a different bodyHash,
+// including by a concurrent request still in flight. replayed is true only
+// when the first store lookup finds the entry; a caller that gets a
+// concurrent request's result, or finds the entry on the re-check inside
+// singleflight, sees replayed=false.
+//
+// Only a terminal result (Status < 500) from fn is persisted. A 5xx result
+// (backpressure, a publish error, ...) is returned to this caller but never
+// saved, so a client retrying with the same key after a transient failure
+// re-runs fn instead of replaying the failure for the rest of the TTL.
+func (m *Manager) Execute(ctx context.Context, key, bodyHash string, fn func(ctx context.Context) (Entry, error)) (entry Entry, replayed bool, err error) {
+	if existing, ok, err := m.store.Get(ctx, key); err != nil {
+		return Entry{}, false, err
+	} else if ok {
+		if existing.BodyHash != bodyHash {
+			return Entry{}, false, ErrConflict
+		}
+		metrics.RecordDedupeHit()
+		return existing, true, nil
+	}
+	metrics.RecordDedupeMiss()
+
+	v, err, _ := m.group.Do(key, func() (any, error) {
+		// Re-check the store: another goroutine may have finished and saved
+		// while we were waiting to enter this singleflight call.
+		if existing, ok, gerr := m.store.Get(ctx, key); gerr != nil {
+			return Entry{}, gerr
+		} else if ok {
+			if existing.BodyHash != bodyHash {
+				return Entry{}, ErrConflict
+			}
+			return existing, nil
+		}
+
+		result, ferr := fn(ctx)
+		if ferr != nil {
+			return Entry{}, ferr
+		}
+		// Tag even an uncached 5xx result, so the check after Do can tell
+		// which body it belongs to.
+		result.BodyHash = bodyHash
+
+		// Don't cache transient failures: a retry with the same key should
+		// re-run fn, not replay a 5xx for the rest of the TTL.
+		if result.Status >= 500 {
+			return result, nil
+		}
+
+		result.BodyHash = bodyHash
+		result.ExpiresAt = time.Now().Add(m.ttl)
+
+		if serr := m.store.Save(ctx, key, result); serr != nil {
+			return Entry{}, serr
+		}
+		return result, nil
+	})
+	if err != nil {
+		return Entry{}, false, err
+	}
+	// singleflight shares one result among all concurrent callers with this
+	// key, so a caller whose body differs from the one fn ran for conflicts.
+	entry = v.(Entry)
+	if entry.BodyHash != bodyHash {
+		return Entry{}, false, ErrConflict
+	}
+	return entry, false, nil
+}
@@ -0,0 +1,45 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process TTL map. Good for local dev and single-
+// instance deployments; use RedisStore once POST /events is load-balanced
+// across more than one instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		delete(s.entries, key)
+		return Entry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, key string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = entry
+	return nil
+}
+
+var _ Store = (*MemoryStore)(nil)
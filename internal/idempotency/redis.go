@@ -0,0 +1,66 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore backs idempotency entries with Redis, so POST /events can be
+// load-balanced across instances and still dedupe correctly.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore wraps an already-connected client. Keys are stored as
+// prefix+key; pick a prefix that won't collide with other uses of the same
+// Redis instance.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	raw, err := s.client.Get(ctx, s.redisKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("idempotency: redis get: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return Entry{}, false, fmt.Errorf("idempotency: decode entry: %w", err)
+	}
+	return entry, true, nil
+}
+
+func (s *RedisStore) Save(ctx context.Context, key string, entry Entry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("idempotency: encode entry: %w", err)
+	}
+
+	ttl := time.Until(entry.ExpiresAt)
+	if ttl <= 0 {
+		// Already expired by the time we'd write it; still write it with a
+		// short TTL rather than skip the write and complicate the happy path.
+		ttl = time.Second
+	}
+
+	if err := s.client.Set(ctx, s.redisKey(key), raw, ttl).Err(); err != nil {
+		return fmt.Errorf("idempotency: redis set: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) redisKey(key string) string {
+	return s.prefix + key
+}
+
+var _ Store = (*RedisStore)(nil)
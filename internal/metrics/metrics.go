@@ -0,0 +1,166 @@
+// Package metrics registers the Prometheus collectors for this service and
+// exposes small helpers for recording against them, so handler/middleware
+// code doesn't need to import prometheus directly.
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultCardinalityCap bounds how many distinct type/source label
+// values we'll track before folding the rest into "other". See
+// CardinalityGuard.
+const DefaultCardinalityCap = 50
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests processed, labeled by method/path/status.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request duration in seconds, labeled by method/path/status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+
+	eventsIngestedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_ingested_total",
+		Help: "Total events successfully published to a sink, labeled by type/source.",
+	}, []string{"type", "source"})
+
+	eventsRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "events_rejected_total",
+		Help: "Total events rejected before/instead of publishing, labeled by reason.",
+	}, []string{"reason"})
+
+	buildInfo = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "build_info",
+		Help: "Always 1; presence lets dashboards confirm the process is scraped.",
+	})
+
+	idempotencyHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "idempotency_hits_total",
+		Help: "Total POST /events requests replayed from the idempotency store.",
+	})
+
+	idempotencyMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "idempotency_misses_total",
+		Help: "Total POST /events requests with an Idempotency-Key not yet seen.",
+	})
+)
+
+func init() {
+	buildInfo.Set(1)
+}
+
+// RegisterSinkStats wires a sink's Stats.Snapshot into gauges labeled by
+// sink name, so the per-sink accept/reject/latency numbers sink.Stats
+// already tracks actually show up on /metrics.
+//
+// Notes to self:
+//   - GaugeFunc reads snapshot lazily on every scrape rather than polling it
+//     on a timer, so there's no background goroutine to manage.
+//   - Only one sink is ever configured per process (see cmd/event-ingest),
+//     so this is called at most once; it's still labeled by name in case
+//     that changes later.
+func RegisterSinkStats(name string, snapshot func() (accepted, rejected uint64, avgLatency time.Duration)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "sink_accepted_total",
+		Help:        "Events accepted by the configured sink.",
+		ConstLabels: prometheus.Labels{"sink": name},
+	}, func() float64 {
+		accepted, _, _ := snapshot()
+		return float64(accepted)
+	})
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "sink_rejected_total",
+		Help:        "Events rejected by the configured sink.",
+		ConstLabels: prometheus.Labels{"sink": name},
+	}, func() float64 {
+		_, rejected, _ := snapshot()
+		return float64(rejected)
+	})
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "sink_publish_latency_seconds",
+		Help:        "Mean observed sink publish latency in seconds.",
+		ConstLabels: prometheus.Labels{"sink": name},
+	}, func() float64 {
+		_, _, avgLatency := snapshot()
+		return avgLatency.Seconds()
+	})
+}
+
+// ObserveHTTPRequest records one request's outcome. Called from
+// middleware.Logging so every route gets instrumented for free.
+func ObserveHTTPRequest(method, path string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	httpRequestsTotal.WithLabelValues(method, path, statusLabel).Inc()
+	httpRequestDuration.WithLabelValues(method, path, statusLabel).Observe(duration.Seconds())
+}
+
+// RecordIngested bumps events_ingested_total for an accepted event.
+func RecordIngested(eventType, source string) {
+	eventsIngestedTotal.WithLabelValues(eventType, source).Inc()
+}
+
+// RecordRejected bumps events_rejected_total for a rejected event/request.
+func RecordRejected(reason string) {
+	eventsRejectedTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordDedupeHit bumps idempotency_hits_total.
+func RecordDedupeHit() {
+	idempotencyHitsTotal.Inc()
+}
+
+// RecordDedupeMiss bumps idempotency_misses_total.
+func RecordDedupeMiss() {
+	idempotencyMissesTotal.Inc()
+}
+
+// CardinalityGuard caps how many distinct label values get reported before
+// folding anything new into "other".
+//
+// Notes to self:
+//   - evt.Type/evt.Source come straight from client-supplied JSON; without
+//     this, a misbehaving or malicious client could mint an unbounded number
+//     of Prometheus label values and blow up memory on the /metrics scrape.
+type CardinalityGuard struct {
+	max int
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// NewCardinalityGuard returns a guard that tracks up to max distinct values.
+func NewCardinalityGuard(max int) *CardinalityGuard {
+	return &CardinalityGuard{max: max, seen: make(map[string]struct{})}
+}
+
+// Label returns value unchanged if it's already known or there's still room
+// to track a new one, otherwise "other". An empty value is always "other".
+func (g *CardinalityGuard) Label(value string) string {
+	if value == "" {
+		return "other"
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.seen[value]; ok {
+		return value
+	}
+	if len(g.seen) >= g.max {
+		return "other"
+	}
+	g.seen[value] = struct{}{}
+	return value
+}
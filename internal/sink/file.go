@@ -0,0 +1,141 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/SamBird/learning-go-by-building/pkg/model"
+)
+
+// FileSink appends events as JSON-lines to a file, rotating to a new file
+// once the current one crosses maxBytes.
+//
+// Notes to self:
+//   - Publish hands the event to a bounded channel and a single background
+//     goroutine does the actual write. That keeps request handlers from
+//     blocking on disk I/O, and gives us a natural backpressure signal
+//     (ErrBackpressure) once the channel fills up instead of an unbounded
+//     queue that could OOM the process.
+type FileSink struct {
+	Stats
+
+	path     string
+	maxBytes int64
+	queue    chan model.Event
+	done     chan struct{}
+
+	mu       sync.Mutex
+	file     *os.File
+	curBytes int64
+}
+
+// NewFileSink opens (creating if needed) path for append and starts the
+// background writer. queueSize bounds how many events can be buffered before
+// Publish starts returning ErrBackpressure.
+func NewFileSink(path string, maxBytes int64, queueSize int) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("sink: open file sink: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("sink: stat file sink: %w", err)
+	}
+
+	fs := &FileSink{
+		path:     path,
+		maxBytes: maxBytes,
+		queue:    make(chan model.Event, queueSize),
+		done:     make(chan struct{}),
+		file:     f,
+		curBytes: info.Size(),
+	}
+	go fs.run()
+	return fs, nil
+}
+
+func (fs *FileSink) Publish(ctx context.Context, evt model.Event) error {
+	start := time.Now()
+
+	select {
+	case fs.queue <- evt:
+		fs.observe(nil, time.Since(start))
+		return nil
+	default:
+		fs.observe(ErrBackpressure, time.Since(start))
+		return ErrBackpressure
+	}
+}
+
+// Close stops the background writer and closes the underlying file. It waits
+// for the writer to finish every event already queued; callers must stop
+// calling Publish first, since sending on the closed queue panics.
+func (fs *FileSink) Close() error {
+	close(fs.queue)
+	<-fs.done
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.file.Close()
+}
+
+func (fs *FileSink) run() {
+	defer close(fs.done)
+	for evt := range fs.queue {
+		if err := fs.write(evt); err != nil {
+			// Notes to self: nowhere good to surface this from a background
+			// goroutine yet; log/slog wiring for sinks can come later.
+			fmt.Fprintf(os.Stderr, "sink: file sink write failed: %v\n", err)
+		}
+	}
+}
+
+func (fs *FileSink) write(evt model.Event) error {
+	line, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("sink: marshal event: %w", err)
+	}
+	line = append(line, '\n')
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.maxBytes > 0 && fs.curBytes+int64(len(line)) > fs.maxBytes {
+		if err := fs.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := fs.file.Write(line)
+	fs.curBytes += int64(n)
+	return err
+}
+
+// rotateLocked renames the current file aside (suffixed with a timestamp)
+// and opens a fresh one at fs.path. Callers must hold fs.mu.
+func (fs *FileSink) rotateLocked() error {
+	if err := fs.file.Close(); err != nil {
+		return fmt.Errorf("sink: close file before rotation: %w", err)
+	}
+
+	rotated := fmt.Sprintf("%s.%d", fs.path, time.Now().UTC().UnixNano())
+	if err := os.Rename(fs.path, rotated); err != nil {
+		return fmt.Errorf("sink: rotate file: %w", err)
+	}
+
+	f, err := os.OpenFile(fs.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("sink: open file after rotation: %w", err)
+	}
+
+	fs.file = f
+	fs.curBytes = 0
+	return nil
+}
+
+var _ Sink = (*FileSink)(nil)
@@ -0,0 +1,67 @@
+package sink
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SamBird/learning-go-by-building/pkg/model"
+)
+
+// MemorySink keeps the last N events in a bounded ring buffer. It never
+// backpressures (the oldest event is simply dropped to make room), which
+// makes it a good default for local dev, tests, and the GET /events debug
+// endpoint.
+type MemorySink struct {
+	Stats
+
+	mu     sync.Mutex
+	buf    []model.Event
+	next   int
+	filled bool
+}
+
+// NewMemorySink returns a MemorySink that retains up to capacity events.
+// Notes to self:
+// - capacity <= 0 would make the ring buffer meaningless, so floor it at 1.
+func NewMemorySink(capacity int) *MemorySink {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemorySink{buf: make([]model.Event, capacity)}
+}
+
+func (m *MemorySink) Publish(ctx context.Context, evt model.Event) error {
+	start := time.Now()
+
+	m.mu.Lock()
+	m.buf[m.next] = evt
+	m.next = (m.next + 1) % len(m.buf)
+	if m.next == 0 {
+		m.filled = true
+	}
+	m.mu.Unlock()
+
+	m.observe(nil, time.Since(start))
+	return nil
+}
+
+// List returns the retained events, oldest first.
+func (m *MemorySink) List() []model.Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.filled {
+		out := make([]model.Event, m.next)
+		copy(out, m.buf[:m.next])
+		return out
+	}
+
+	out := make([]model.Event, len(m.buf))
+	copy(out, m.buf[m.next:])
+	copy(out[len(m.buf)-m.next:], m.buf[:m.next])
+	return out
+}
+
+var _ Sink = (*MemorySink)(nil)
+var _ Lister = (*MemorySink)(nil)
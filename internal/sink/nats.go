@@ -0,0 +1,50 @@
+//go:build nats
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/SamBird/learning-go-by-building/pkg/model"
+)
+
+// NATSSink publishes events to a NATS subject.
+//
+// Notes to self:
+//   - Built behind the "nats" build tag, same reasoning as KafkaSink: keep the
+//     nats.go out of the default build and opt in with `go build -tags nats`.
+type NATSSink struct {
+	Stats
+
+	conn    *nats.Conn
+	subject string
+}
+
+// NewNATSSink wraps an already-connected *nats.Conn for publishing to subject.
+func NewNATSSink(conn *nats.Conn, subject string) *NATSSink {
+	return &NATSSink{conn: conn, subject: subject}
+}
+
+func (n *NATSSink) Publish(ctx context.Context, evt model.Event) error {
+	start := time.Now()
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		n.observe(err, time.Since(start))
+		return fmt.Errorf("sink: marshal event for nats: %w", err)
+	}
+
+	err = n.conn.Publish(n.subject, payload)
+	n.observe(err, time.Since(start))
+	if err != nil {
+		return fmt.Errorf("sink: nats publish: %w", err)
+	}
+	return nil
+}
+
+var _ Sink = (*NATSSink)(nil)
@@ -0,0 +1,73 @@
+// Package sink abstracts "what happens to an event after we accept it" so
+// EventHandler doesn't need to know whether events end up in memory, a file,
+// or a message broker.
+package sink
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/SamBird/learning-go-by-building/pkg/model"
+)
+
+// Sink publishes an accepted event somewhere durable (or at least somewhere
+// else). Implementations should be safe for concurrent use; EventHandler
+// calls Publish from every request goroutine.
+type Sink interface {
+	Publish(ctx context.Context, evt model.Event) error
+}
+
+// ErrBackpressure is returned by a Sink when it can't accept more work right
+// now (a full queue, a saturated writer, …). EventHandler maps this to a 503
+// so clients know to retry rather than treating it as a permanent failure.
+var ErrBackpressure = errors.New("sink: backpressured, try again later")
+
+// Lister is an optional capability a Sink can implement to support the
+// GET /events debug endpoint. MemorySink implements it; durable sinks
+// generally don't, since "list everything" isn't a sane operation once
+// events live in Kafka or a growing file.
+type Lister interface {
+	List() []model.Event
+}
+
+// StatsProvider is an optional capability a Sink can implement to expose its
+// embedded Stats. Every Sink in this package embeds Stats, so all of them
+// satisfy this; it exists so callers holding a Sink interface value (e.g.
+// EventHandler) can get at Snapshot without depending on a concrete type.
+type StatsProvider interface {
+	Snapshot() (accepted, rejected uint64, avgLatency time.Duration)
+}
+
+// Stats tracks accept/reject counts and publish latency for a Sink.
+// Notes to self:
+//   - This is deliberately minimal (atomic counters, no histogram buckets).
+//     metrics.RegisterSinkStats exposes these as Prometheus gauges; that is
+//     enough to answer "is this sink healthy".
+type Stats struct {
+	accepted     atomic.Uint64
+	rejected     atomic.Uint64
+	publishNanos atomic.Uint64
+}
+
+func (s *Stats) observe(err error, dur time.Duration) {
+	s.publishNanos.Add(uint64(dur.Nanoseconds()))
+	if err != nil {
+		s.rejected.Add(1)
+		return
+	}
+	s.accepted.Add(1)
+}
+
+// Snapshot returns the accepted/rejected counts and the mean publish latency
+// observed so far.
+func (s *Stats) Snapshot() (accepted, rejected uint64, avgLatency time.Duration) {
+	accepted = s.accepted.Load()
+	rejected = s.rejected.Load()
+	total := accepted + rejected
+	if total == 0 {
+		return accepted, rejected, 0
+	}
+	return accepted, rejected, time.Duration(s.publishNanos.Load() / total)
+}
@@ -1,21 +1,21 @@
 package model
 
-import {
+import (
 	"errors"
 	"strings"
 	"time"
-}
+)
 
 // Event is the inbound unit of data we're ingesting.
 // Notes to self:
 // - Keep it simple early on: a small stable "envelope" with a flexible Payload.
 // - Later, I can evolve Payload into a typed struct per event type, or validate against schemas.
 type Event struct {
-	ID			string		`json:"id"`
-	Type		string		`json:"id"`
-	Source		string		`json:"id"`
-	Timestamp	time.Time	`json:"id"`
-	Payload		any			`json:"id"` // "any" is an alias for interface{} in Go 1.18+
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Source    string    `json:"source"`
+	Timestamp time.Time `json:"timestamp"`
+	Payload   any       `json:"payload"` // "any" is an alias for interface{} in Go 1.18+
 }
 
 // Validate does minimal checks.
@@ -24,19 +24,33 @@ type Event struct {
 // - Keep validation close to the model for now; could move to a validator package later.
 func (e Event) Validate() error {
 	if strings.TrimSpace(e.ID) == "" {
-		return errors.New("ID is required.")
+		return errors.New("id is required")
 	}
 
 	if strings.TrimSpace(e.Type) == "" {
-		return errors.New("Type is required.")
+		return errors.New("type is required")
 	}
 
 	if strings.TrimSpace(e.Source) == "" {
-		return errors.New("Source is required.")
+		return errors.New("source is required")
 	}
 
 	// Timestamp is optional for v1; the handler will default it server-side if missing.
-	return nil;
+	return nil
+}
+
+// ValidateWithRegistry runs the envelope checks from Validate and then, if
+// reg has a schema registered for e.Type, validates Payload against it. A
+// nil registry (or a type with no registered schema) is permissive, so
+// callers can roll out per-type schemas incrementally.
+func (e Event) ValidateWithRegistry(reg *SchemaRegistry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	if reg == nil {
+		return nil
+	}
+	return reg.Validate(e.Type, e.Payload)
 }
 
 /*
@@ -44,4 +58,4 @@ Useful links:
 - Effective Go: https://go.dev/doc/effective_go
 - Errors in Go: https://go.dev/blog/errors-are-values
 - time.Time + RFC3339: https://pkg.go.dev/time
-*/
\ No newline at end of file
+*/
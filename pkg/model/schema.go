@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaRegistry validates event payloads against JSON Schema (draft
+// 2020-12) documents, one per event type.
+//
+// Notes to self:
+//   - Keyed by event type rather than a single global schema: different event
+//     types legitimately have very different payload shapes.
+type SchemaRegistry struct {
+	schemas map[string]*jsonschema.Schema
+}
+
+// NewSchemaRegistry returns an empty registry; every type is permissive
+// until a schema is registered for it.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]*jsonschema.Schema)}
+}
+
+// LoadSchemaRegistry compiles every "<event-type>.json" file in dir into a
+// registry keyed by event type (the file's base name without extension).
+// Notes to self:
+//   - Draft 2020-12 is the current JSON Schema version; the jsonschema
+//     package auto-detects it from $schema but we pin the compiler default in
+//     case a schema file omits it.
+func LoadSchemaRegistry(dir string) (*SchemaRegistry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("model: read schema dir %q: %w", dir, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+
+	reg := NewSchemaRegistry()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		eventType := strings.TrimSuffix(entry.Name(), ".json")
+		path := filepath.Join(dir, entry.Name())
+
+		schema, err := compiler.Compile(path)
+		if err != nil {
+			return nil, fmt.Errorf("model: compile schema %s: %w", entry.Name(), err)
+		}
+		reg.schemas[eventType] = schema
+	}
+	return reg, nil
+}
+
+// Validate checks payload against the schema registered for eventType. If no
+// schema is registered, Validate is a no-op, so rolling out schemas per type
+// doesn't require a flag day.
+func (r *SchemaRegistry) Validate(eventType string, payload any) error {
+	schema, ok := r.schemas[eventType]
+	if !ok {
+		return nil
+	}
+
+	// The jsonschema package validates decoded-JSON-shaped values (maps,
+	// slices, float64s, …). Round-tripping through encoding/json normalizes
+	// payload into that shape regardless of how the caller built it.
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("model: marshal payload for type %q: %w", eventType, err)
+	}
+
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("model: unmarshal payload for type %q: %w", eventType, err)
+	}
+
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("model: payload failed schema for type %q: %w", eventType, err)
+	}
+	return nil
+}